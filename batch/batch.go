@@ -0,0 +1,212 @@
+// Package batch runs schemat over many files concurrently with a worker
+// pool sized to GOMAXPROCS, backing the "check" and "diff" subcommands as
+// well as in-place formatting of a whole tree. A sequential pass is the
+// bottleneck for CI runs over large codebases; this package exists so a
+// single invocation across thousands of files gets fast feedback, while
+// still reporting results in the same order as the input regardless of
+// which file a worker happened to finish first.
+package batch
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/raviqqe/schemat/config"
+	"github.com/raviqqe/schemat/format"
+)
+
+// Mode selects what Run does with each file once it knows whether
+// formatting would change it.
+type Mode int
+
+const (
+	// ModeWrite formats files in place, like the default one-shot CLI mode.
+	ModeWrite Mode = iota
+	// ModeCheck only reports which files would change.
+	ModeCheck
+	// ModeDiff prints a unified diff for each file that would change.
+	ModeDiff
+)
+
+// FileResult is the outcome of processing one file.
+type FileResult struct {
+	Path       string `json:"path"`
+	Status     string `json:"status"` // "unchanged", "changed" or "error"
+	ByteDelta  int    `json:"byteDelta"`
+	DurationMS int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+
+	// Diff holds the unified diff for a changed file in ModeDiff. It is
+	// not part of the JSON summary, which reports metadata, not content.
+	Diff string `json:"-"`
+}
+
+// Summary is the result of a batch run, with Files always in the same
+// order as the paths Run was given.
+type Summary struct {
+	Files   []FileResult `json:"files"`
+	Changed int          `json:"changed"`
+	Failed  int          `json:"failed"`
+}
+
+// Runner runs schemat over many files.
+type Runner struct {
+	Loader *config.Loader
+	Mode   Mode
+
+	// Workers caps how many files are processed at once. Zero means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+
+	// IndentOverride and MaxWidthOverride, when non-zero, replace the
+	// corresponding setting from each file's resolved config, mirroring
+	// the one-shot CLI's --indent/--max-width flags.
+	IndentOverride   int
+	MaxWidthOverride int
+
+	// Progress, if non-nil, receives a "N/M files, K changed" line after
+	// every completed file, overwriting the previous one with \r. Callers
+	// typically only set this when stdout is a TTY.
+	Progress *os.File
+}
+
+// Run formats every path, in parallel, and returns a Summary ordered the
+// same way paths is.
+func (r *Runner) Run(paths []string) Summary {
+	workers := r.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	results := make([]FileResult, len(paths))
+	jobs := make(chan int)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		done    int
+		changed int
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for idx := range jobs {
+				result := r.process(paths[idx])
+				results[idx] = result
+
+				mu.Lock()
+				done++
+				if result.Status == "changed" {
+					changed++
+				}
+				if r.Progress != nil {
+					fmt.Fprintf(r.Progress, "\r%d/%d files, %d changed", done, len(paths), changed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if r.Progress != nil && len(paths) > 0 {
+		fmt.Fprintln(r.Progress)
+	}
+
+	summary := Summary{Files: results}
+
+	for _, result := range results {
+		switch result.Status {
+		case "changed":
+			summary.Changed++
+		case "error":
+			summary.Failed++
+		}
+	}
+
+	return summary
+}
+
+func (r *Runner) process(path string) FileResult {
+	start := time.Now()
+	result := FileResult{Path: path}
+
+	if err := r.run(path, &result); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+
+	return result
+}
+
+func (r *Runner) run(path string, result *FileResult) error {
+	cfg, err := r.Loader.Load(path)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	opts, err := r.Loader.FormatOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	if r.IndentOverride != 0 {
+		opts.IndentSize = r.IndentOverride
+	}
+
+	if r.MaxWidthOverride != 0 {
+		opts.MaxWidth = r.MaxWidthOverride
+	}
+
+	out, changed, err := format.Diff(src, opts)
+	if err != nil {
+		return err
+	}
+
+	result.ByteDelta = len(out) - len(src)
+
+	if !changed {
+		result.Status = "unchanged"
+		return nil
+	}
+
+	result.Status = "changed"
+
+	switch r.Mode {
+	case ModeWrite:
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, out, info.Mode().Perm())
+	case ModeDiff:
+		result.Diff = unifiedDiff(path, src, out)
+	case ModeCheck:
+		// The Status field alone answers "would this file change?".
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/raviqqe/schemat/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunCheckReportsChangedFilesWithoutModifyingThem(t *testing.T) {
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.scm")
+	b := filepath.Join(dir, "b.scm")
+	writeFile(t, a, "(define   x 1)")
+	writeFile(t, b, "(define x 1)\n")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeCheck}
+	summary := runner.Run([]string{a, b})
+
+	if summary.Changed != 1 || summary.Failed != 0 {
+		t.Fatalf("Summary = %+v, want 1 changed, 0 failed", summary)
+	}
+
+	if summary.Files[0].Path != a || summary.Files[0].Status != "changed" {
+		t.Errorf("Files[0] = %+v, want a.scm changed", summary.Files[0])
+	}
+
+	if summary.Files[1].Path != b || summary.Files[1].Status != "unchanged" {
+		t.Errorf("Files[1] = %+v, want b.scm unchanged", summary.Files[1])
+	}
+
+	got, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "(define   x 1)" {
+		t.Errorf("ModeCheck modified %s on disk", a)
+	}
+}
+
+func TestRunWriteFormatsInPlace(t *testing.T) {
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.scm")
+	writeFile(t, a, "(define   x 1)")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeWrite}
+	runner.Run([]string{a})
+
+	got, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "(define x 1)\n" {
+		t.Errorf("file content = %q, want %q", got, "(define x 1)\n")
+	}
+}
+
+func TestRunWriteHonorsOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.scm")
+	writeFile(t, a, "(define x 1)")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeWrite, MaxWidthOverride: 1, IndentOverride: 4}
+	runner.Run([]string{a})
+
+	got, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "(define\n    x\n    1)\n"
+	if string(got) != want {
+		t.Errorf("file content = %q, want %q", got, want)
+	}
+}
+
+func TestRunDiffProducesUnifiedDiff(t *testing.T) {
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.scm")
+	writeFile(t, a, "(define   x 1)")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeDiff}
+	summary := runner.Run([]string{a})
+
+	diff := summary.Files[0].Diff
+	if diff == "" {
+		t.Fatal("Diff is empty, want a unified diff")
+	}
+
+	for _, want := range []string{"--- " + a, "+++ " + a, "-(define   x 1)", "+(define x 1)"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff = %q, want it to contain %q", diff, want)
+		}
+	}
+}
+
+func TestRunReportsErrorsWithoutStoppingOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	ok := filepath.Join(dir, "ok.scm")
+	bad := filepath.Join(dir, "bad.scm")
+	writeFile(t, ok, "(a)")
+	writeFile(t, bad, "(a")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeCheck}
+	summary := runner.Run([]string{ok, bad})
+
+	if summary.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", summary.Failed)
+	}
+
+	statuses := map[string]string{}
+	for _, f := range summary.Files {
+		statuses[f.Path] = f.Status
+	}
+
+	if statuses[bad] != "error" {
+		t.Errorf("bad.scm status = %q, want %q", statuses[bad], "error")
+	}
+}
+
+func TestRunAppliesConfigScripts(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "rules.js"), `
+schemat.registerRule("for/fold", function(node) {
+	return {kind: "body", bodyStart: node.column + 4};
+});
+`)
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `max_width = 1`+"\n"+`scripts = ["rules.js"]`)
+
+	a := filepath.Join(dir, "a.scm")
+	writeFile(t, a, "(for/fold a b)")
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeDiff}
+	summary := runner.Run([]string{a})
+
+	if summary.Failed != 0 {
+		t.Fatalf("Summary = %+v, want 0 failed", summary)
+	}
+
+	if !strings.Contains(summary.Files[0].Diff, "+    a") {
+		t.Errorf("Diff = %q, want it to reflect the config's custom indentation rule", summary.Files[0].Diff)
+	}
+}
+
+func TestRunPreservesInputOrderRegardlessOfCompletionOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 0; i < 20; i++ {
+		p := filepath.Join(dir, string(rune('a'+i))+".scm")
+		writeFile(t, p, "(a)\n")
+		paths = append(paths, p)
+	}
+
+	runner := &Runner{Loader: config.NewLoader(), Mode: ModeCheck}
+	summary := runner.Run(paths)
+
+	var got []string
+	for _, f := range summary.Files {
+		got = append(got, f.Path)
+	}
+
+	if !sort.StringsAreSorted(got) {
+		t.Errorf("Files order = %v, want sorted (same as input)", got)
+	}
+}
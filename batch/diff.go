@@ -0,0 +1,81 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unifiedDiff renders a minimal unified diff between oldSrc and newSrc:
+// the lines common to both the start and the end of the files are
+// elided, and the single hunk in between is printed in full, with no
+// extra context lines.
+func unifiedDiff(path string, oldSrc, newSrc []byte) string {
+	oldLines := splitLines(string(oldSrc))
+	newLines := splitLines(string(newSrc))
+
+	start, oldEnd, newEnd := diffBounds(oldLines, newLines)
+
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", path, path)
+	fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", start+1, oldEnd-start, start+1, newEnd-start)
+
+	for _, line := range oldLines[start:oldEnd] {
+		buf.WriteString("-" + line)
+		buf.WriteString(trailingNewline(line))
+	}
+
+	for _, line := range newLines[start:newEnd] {
+		buf.WriteString("+" + line)
+		buf.WriteString(trailingNewline(line))
+	}
+
+	return buf.String()
+}
+
+// diffBounds returns the index the two line slices first differ at, and
+// the end index (exclusive) in each slice where they start matching again
+// from the back.
+func diffBounds(oldLines, newLines []string) (start, oldEnd, newEnd int) {
+	for start < len(oldLines) && start < len(newLines) && oldLines[start] == newLines[start] {
+		start++
+	}
+
+	oldEnd, newEnd = len(oldLines), len(newLines)
+	for oldEnd > start && newEnd > start && oldLines[oldEnd-1] == newLines[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+
+	return start, oldEnd, newEnd
+}
+
+// splitLines splits s into lines that each still end with their own "\n",
+// except possibly the last.
+func splitLines(s string) []string {
+	var lines []string
+
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			lines = append(lines, s[start:i+1])
+			start = i + 1
+		}
+	}
+
+	if start < len(s) {
+		lines = append(lines, s[start:])
+	}
+
+	return lines
+}
+
+// trailingNewline returns "" for lines already carrying their own
+// terminator, since splitLines only omits one for a file's last line.
+func trailingNewline(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return ""
+	}
+
+	return "\n"
+}
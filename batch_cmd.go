@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/raviqqe/schemat/batch"
+	"github.com/raviqqe/schemat/config"
+
+	flag "github.com/spf13/pflag"
+)
+
+// runBatch implements the "check" and "diff" subcommands: both run the
+// formatter over many files in parallel via the batch package and differ
+// only in what they do with a file that would change.
+func runBatch(name string, args []string, mode batch.Mode) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	jsonOutput := fs.String("format", "", `output format: "json" for a machine-readable summary`)
+	allowRemote := fs.Bool("allow-remote-config", false, `allow schemat.toml "import" entries to fetch http(s) URLs`)
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		fail(fmt.Errorf("schemat %s: no files given", name))
+	}
+
+	loader := config.NewLoader()
+	loader.AllowRemoteImports = *allowRemote
+
+	runner := &batch.Runner{Loader: loader, Mode: mode}
+	if isTerminal(os.Stdout) {
+		runner.Progress = os.Stderr
+	}
+
+	summary := runner.Run(paths)
+
+	if *jsonOutput == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+			fail(err)
+		}
+	} else {
+		printBatchResults(summary, mode)
+	}
+
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+
+	if mode == batch.ModeCheck && summary.Changed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printBatchResults(summary batch.Summary, mode batch.Mode) {
+	for _, f := range summary.Files {
+		if f.Status == "error" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", f.Path, f.Error)
+			continue
+		}
+
+		if f.Status != "changed" {
+			continue
+		}
+
+		switch mode {
+		case batch.ModeDiff:
+			fmt.Print(f.Diff)
+		case batch.ModeCheck, batch.ModeWrite:
+			fmt.Println(f.Path)
+		}
+	}
+}
+
+// isTerminal reports whether f is connected to a terminal, the condition
+// under which progress reporting is worth the escape codes.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
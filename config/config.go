@@ -0,0 +1,211 @@
+// Package config implements schemat's hierarchical project configuration.
+//
+// Configuration lives in "schemat.toml" or ".schemat.toml" files. A file
+// discovered in a directory applies to every source file at or below that
+// directory, and a file found deeper in the tree overrides the settings of
+// any file found above it (per-directory overrides). A config file may also
+// "import" another config by local path or URL; imports are merged first,
+// in order, so a later import overrides an earlier one, and the importing
+// file's own settings override all of its imports.
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Dialect names the Scheme dialect a file is written in. It is parsed and
+// merged like every other setting, but Loader.FormatOptions does not pass
+// it on, so it does not change how a file is formatted.
+type Dialect string
+
+// Supported dialects.
+const (
+	DialectScheme  Dialect = "scheme"
+	DialectRacket  Dialect = "racket"
+	DialectGuile   Dialect = "guile"
+	DialectChicken Dialect = "chicken"
+)
+
+// FormRule records an indentation override for a single special form (e.g.
+// "define-syntax" or a user macro), set by a "forms.<name>.indent" key.
+// Forms are parsed and merged like every other setting, but
+// Loader.FormatOptions does not pass them on, so they do not change how a
+// file is formatted.
+type FormRule struct {
+	// Indent is the number of spaces used for the form's body, relative to
+	// the form's own column. A zero value means "use the file's default".
+	Indent int
+}
+
+// Rule pins a set of overrides to files matching any of Paths, a list of
+// glob patterns (supporting "**" for arbitrary depth) relative to the
+// directory the defining config file lives in. Rules from a config fetched
+// by URL match no local file. Config is layered on as it was parsed: an
+// "import" inside a rule is not resolved.
+type Rule struct {
+	Paths  []string
+	Config Config
+
+	// baseDir is the directory of the config file that defined this rule,
+	// against which Paths are matched. It is filled in during resolution,
+	// since the TOML parser itself has no notion of filesystem location.
+	baseDir string
+}
+
+// Config is a fully or partially specified set of formatting settings. Zero
+// values mean "unset" and are filled in by whatever config is merged next.
+type Config struct {
+	Indent   int
+	MaxWidth int
+	Dialect  Dialect
+	Forms    map[string]FormRule
+
+	// Imports lists other configs (local paths or URLs) to merge before
+	// this config's own settings are applied.
+	Imports []string
+
+	// Rules are per-glob overrides layered on top of the merged base
+	// config when resolving a specific file.
+	Rules []Rule
+
+	// Scripts lists paths, relative to this config file, of ECMAScript
+	// plugins (see the script package) that register custom per-form
+	// indentation rules. Paths in a config fetched by URL are left as
+	// written.
+	Scripts []string
+}
+
+// Default is used when no config file is found at all.
+var Default = Config{
+	Indent:   2,
+	MaxWidth: 80,
+	Dialect:  DialectScheme,
+}
+
+// merge overlays the non-zero fields of override onto base and returns the
+// result, leaving both arguments unmodified.
+func merge(base, override Config) Config {
+	result := base
+
+	if override.Indent != 0 {
+		result.Indent = override.Indent
+	}
+
+	if override.MaxWidth != 0 {
+		result.MaxWidth = override.MaxWidth
+	}
+
+	if override.Dialect != "" {
+		result.Dialect = override.Dialect
+	}
+
+	if len(override.Forms) > 0 {
+		forms := make(map[string]FormRule, len(result.Forms)+len(override.Forms))
+		for k, v := range result.Forms {
+			forms[k] = v
+		}
+
+		for k, v := range override.Forms {
+			forms[k] = v
+		}
+
+		result.Forms = forms
+	}
+
+	if len(override.Rules) > 0 {
+		result.Rules = append(append([]Rule{}, result.Rules...), override.Rules...)
+	}
+
+	if len(override.Scripts) > 0 {
+		result.Scripts = append(append([]string{}, result.Scripts...), override.Scripts...)
+	}
+
+	// Imports are consumed during resolution and never carried forward.
+	result.Imports = nil
+
+	return result
+}
+
+// resolveFile loads a single config file and merges in its imports, so the
+// returned Config has no outstanding Imports of its own. allowRemote gates
+// whether an "import" naming an http(s) URL is actually fetched: off by
+// default, since walking a tree (e.g. during "schemat check" in CI) can
+// otherwise turn a formatter run into unsolicited outbound network
+// requests driven by a committed config file.
+func resolveFile(path string, fetch fetchFunc, seen map[string]bool, allowRemote bool) (Config, error) {
+	if seen[path] {
+		return Config{}, fmt.Errorf("config: import cycle detected at %s", path)
+	}
+	seen[path] = true
+	defer delete(seen, path)
+
+	if isURL(path) && !allowRemote {
+		return Config{}, fmt.Errorf("config: refusing to fetch remote import %s (pass --allow-remote-config to allow this)", path)
+	}
+
+	data, err := fetch(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+
+	cfg, err := parseTOML(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	if isURL(path) {
+		dir = path
+	}
+
+	for i := range cfg.Rules {
+		cfg.Rules[i].baseDir = dir
+	}
+
+	if !isURL(path) {
+		for i, s := range cfg.Scripts {
+			if !isURL(s) {
+				cfg.Scripts[i] = filepath.Join(filepath.Dir(path), s)
+			}
+		}
+
+		for _, rule := range cfg.Rules {
+			for i, s := range rule.Config.Scripts {
+				if !isURL(s) {
+					rule.Config.Scripts[i] = filepath.Join(filepath.Dir(path), s)
+				}
+			}
+		}
+	}
+
+	merged := Config{}
+
+	for _, imp := range cfg.Imports {
+		importPath := imp
+		if !isURL(imp) {
+			importPath = filepath.Join(filepath.Dir(path), imp)
+		}
+
+		imported, err := resolveFile(importPath, fetch, seen, allowRemote)
+		if err != nil {
+			return Config{}, err
+		}
+
+		merged = merge(merged, imported)
+	}
+
+	cfg.Imports = nil
+
+	return merge(merged, cfg), nil
+}
+
+func isURL(s string) bool {
+	for _, prefix := range []string{"http://", "https://"} {
+		if len(s) >= len(prefix) && s[:len(prefix)] == prefix {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoaderAppliesDefaults(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := NewLoader().Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Indent != Default.Indent || cfg.MaxWidth != Default.MaxWidth {
+		t.Errorf("Load() = %+v, want defaults %+v", cfg, Default)
+	}
+}
+
+func TestLoaderPerDirectoryOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `indent = 2`+"\n"+`dialect = "scheme"`)
+	writeFile(t, filepath.Join(dir, "racket", "schemat.toml"), `dialect = "racket"`)
+
+	loader := NewLoader()
+
+	root, err := loader.Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Dialect != DialectScheme {
+		t.Errorf("root dialect = %q, want %q", root.Dialect, DialectScheme)
+	}
+
+	sub, err := loader.Load(filepath.Join(dir, "racket", "b.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sub.Dialect != DialectRacket {
+		t.Errorf("sub dialect = %q, want %q", sub.Dialect, DialectRacket)
+	}
+
+	if sub.Indent != 2 {
+		t.Errorf("sub indent = %d, want inherited value 2", sub.Indent)
+	}
+}
+
+func TestLoaderImportMerging(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "base.toml"), `indent = 4`+"\n"+`max_width = 100`)
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `import = "base.toml"`+"\n"+`max_width = 120`)
+
+	cfg, err := NewLoader().Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Indent != 4 {
+		t.Errorf("Indent = %d, want 4 (from import)", cfg.Indent)
+	}
+
+	if cfg.MaxWidth != 120 {
+		t.Errorf("MaxWidth = %d, want 120 (own value overrides import)", cfg.MaxWidth)
+	}
+}
+
+func TestLoaderRuleGlobOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `indent = 2
+
+[[rules]]
+paths = ["tests/**"]
+indent = 4`)
+
+	loader := NewLoader()
+
+	main, err := loader.Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if main.Indent != 2 {
+		t.Errorf("main.Indent = %d, want 2", main.Indent)
+	}
+
+	test, err := loader.Load(filepath.Join(dir, "tests", "a_test.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if test.Indent != 4 {
+		t.Errorf("test.Indent = %d, want 4 (from rule)", test.Indent)
+	}
+}
+
+func TestLoaderResolvesScriptPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `scripts = ["rules.js"]`)
+
+	cfg, err := NewLoader().Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "rules.js")
+	if len(cfg.Scripts) != 1 || cfg.Scripts[0] != want {
+		t.Errorf("Scripts = %v, want [%q]", cfg.Scripts, want)
+	}
+}
+
+func TestLoaderRejectsRemoteImportByDefault(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `import = "https://example.com/schemat.toml"`)
+
+	if _, err := NewLoader().Load(filepath.Join(dir, "a.scm")); err == nil {
+		t.Errorf("Load() = nil error, want error for an unopted-in remote import")
+	}
+}
+
+func TestLoaderAllowsRemoteImportWhenOptedIn(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `import = "https://example.com/schemat.toml"`)
+
+	loader := NewLoader()
+	loader.AllowRemoteImports = true
+	loader.fetch = func(path string) ([]byte, error) {
+		if path == "https://example.com/schemat.toml" {
+			return []byte(`indent = 4`), nil
+		}
+
+		return defaultFetch(path)
+	}
+
+	cfg, err := loader.Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Indent != 4 {
+		t.Errorf("Indent = %d, want 4 (from remote import)", cfg.Indent)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"tests/**", "tests/a.scm", true},
+		{"tests/**", "tests/nested/a.scm", true},
+		{"tests/**", "src/a.scm", false},
+		{"*.scm", "a.scm", true},
+		{"*.scm", "a.rkt", false},
+	}
+
+	for _, c := range cases {
+		if got := matchGlob(c.pattern, c.path); got != c.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// fetchFunc reads the raw contents of a config file, whether it lives on
+// disk or behind a URL.
+type fetchFunc func(path string) ([]byte, error)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+func defaultFetch(path string) ([]byte, error) {
+	if isURL(path) {
+		return fetchURL(path)
+	}
+
+	return os.ReadFile(path)
+}
+
+func fetchURL(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: status %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,71 @@
+package config
+
+import "strings"
+
+// matchGlob reports whether path (slash-separated, relative to the config
+// file that defined pattern) matches pattern. Besides the usual single-"*"
+// and "?" wildcards, a path segment of "**" matches zero or more segments,
+// which filepath.Match does not support but rule sets like
+// "tests/**" rely on.
+func matchGlob(pattern, path string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		if matchSegments(pattern[1:], path) {
+			return true
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+
+		return matchSegments(pattern, path[1:])
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	if !globMatch(pattern[0], path[0]) {
+		return false
+	}
+
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// globMatch matches a single path segment against a single glob segment
+// supporting "*" and "?".
+func globMatch(pattern, s string) bool {
+	if pattern == "" {
+		return s == ""
+	}
+
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(s); i++ {
+			if globMatch(pattern[1:], s[i:]) {
+				return true
+			}
+		}
+
+		return false
+	case '?':
+		if s == "" {
+			return false
+		}
+
+		return globMatch(pattern[1:], s[1:])
+	default:
+		if s == "" || s[0] != pattern[0] {
+			return false
+		}
+
+		return globMatch(pattern[1:], s[1:])
+	}
+}
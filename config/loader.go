@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	iradix "github.com/hashicorp/go-immutable-radix"
+
+	"github.com/raviqqe/schemat/script"
+)
+
+// fileNames are tried, in order, in each directory.
+var fileNames = []string{"schemat.toml", ".schemat.toml"}
+
+// Loader resolves the effective Config for a file, caching the merged
+// result per directory so a batch run over many files in the same tree
+// only walks and parses each config file once.
+type Loader struct {
+	fetch fetchFunc
+
+	// AllowRemoteImports permits "import" entries naming an http(s) URL to
+	// actually be fetched. It defaults to false, since a Loader is commonly
+	// pointed at a whole, often contributor-supplied tree (e.g. "schemat
+	// check" in CI), and a committed config file shouldn't be able to turn
+	// that into unsolicited outbound network requests.
+	AllowRemoteImports bool
+
+	mu      sync.Mutex
+	cache   *iradix.Tree
+	engines map[string]*script.Engine
+}
+
+// NewLoader returns a Loader that reads config files from disk (and, once
+// AllowRemoteImports is set, from URLs referenced by "import").
+func NewLoader() *Loader {
+	return &Loader{fetch: defaultFetch, cache: iradix.New(), engines: map[string]*script.Engine{}}
+}
+
+// Load resolves the Config that applies to path, which may be a file or a
+// directory.
+func (l *Loader) Load(path string) (*Config, error) {
+	dir := path
+
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	} else if err != nil {
+		dir = filepath.Dir(path)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := l.resolveDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.forPath(path), nil
+}
+
+// resolvedConfig is the hierarchical merge of every config file from the
+// filesystem root down to a directory, kept separate from per-file rule
+// matching so the cache entry is reusable across every file in dir.
+type resolvedConfig struct {
+	dir  string
+	base Config
+}
+
+func (r *resolvedConfig) forPath(path string) *Config {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	cfg := r.base
+
+	for _, rule := range r.base.Rules {
+		rel, err := filepath.Rel(rule.baseDir, abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, pattern := range rule.Paths {
+			if matchGlob(pattern, rel) {
+				cfg = merge(cfg, rule.Config)
+				break
+			}
+		}
+	}
+
+	return &cfg
+}
+
+func (l *Loader) resolveDir(dir string) (*resolvedConfig, error) {
+	l.mu.Lock()
+	if v, ok := l.cache.Get([]byte(dir)); ok {
+		l.mu.Unlock()
+		return v.(*resolvedConfig), nil
+	}
+	l.mu.Unlock()
+
+	parent := filepath.Dir(dir)
+
+	base := Default
+	if parent != dir {
+		parentCfg, err := l.resolveDir(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		base = parentCfg.base
+	}
+
+	for _, name := range fileNames {
+		p := filepath.Join(dir, name)
+
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+
+		own, err := resolveFile(p, l.fetch, map[string]bool{}, l.AllowRemoteImports)
+		if err != nil {
+			return nil, err
+		}
+
+		base = merge(base, own)
+
+		break
+	}
+
+	result := &resolvedConfig{dir: dir, base: base}
+
+	l.mu.Lock()
+	l.cache, _, _ = l.cache.Insert([]byte(dir), result)
+	l.mu.Unlock()
+
+	return result, nil
+}
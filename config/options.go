@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/raviqqe/schemat/format"
+	"github.com/raviqqe/schemat/script"
+)
+
+// FormatOptions builds the format.Options implied by cfg, reusing a cached
+// script.Engine for cfg.Scripts when one has already been built (see
+// Loader.scriptEngine). It is the one place that translates a resolved
+// Config into format.Options, so the CLI, batch runner and LSP server can't
+// drift from one another on what a config file's "indent", "max_width" and
+// "scripts" settings mean.
+func (l *Loader) FormatOptions(cfg *Config) (format.Options, error) {
+	opts := format.Options{IndentSize: cfg.Indent, MaxWidth: cfg.MaxWidth}
+
+	if len(cfg.Scripts) > 0 {
+		engine, err := l.scriptEngine(cfg.Scripts)
+		if err != nil {
+			return format.Options{}, err
+		}
+
+		opts.Rules = engine
+	}
+
+	return opts, nil
+}
+
+// scriptEngine returns the script.Engine for a given set of plugin scripts,
+// building and caching it on first use. Without this, every call to
+// FormatOptions would construct a fresh goja runtime and re-run every
+// configured script -- once per file in a batch run, and once per
+// formatting request in the LSP server, i.e. on every keystroke-triggered
+// format in an editor.
+func (l *Loader) scriptEngine(scripts []string) (*script.Engine, error) {
+	key := strings.Join(scripts, "\x00")
+
+	l.mu.Lock()
+	if engine, ok := l.engines[key]; ok {
+		l.mu.Unlock()
+		return engine, nil
+	}
+	l.mu.Unlock()
+
+	engine := script.NewEngine()
+
+	for _, path := range scripts {
+		if err := engine.Load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l.mu.Lock()
+	l.engines[key] = engine
+	l.mu.Unlock()
+
+	return engine, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatOptionsCachesScriptEngine(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "rules.js"), `schemat.registerRule("foo", function(node) {
+	return {kind: "body", bodyStart: 2};
+});`)
+	writeFile(t, filepath.Join(dir, "schemat.toml"), `scripts = ["rules.js"]`)
+
+	loader := NewLoader()
+
+	cfg, err := loader.Load(filepath.Join(dir, "a.scm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := loader.FormatOptions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := loader.FormatOptions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Rules != second.Rules {
+		t.Errorf("FormatOptions() built a new script.Engine on the second call, want the cached one reused")
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML schemat's config files use: top-level
+// keys, a "[dialect]"-less flat table, and "[[rules]]" array-of-tables with
+// a "paths" key and nested "forms.<name>.indent" style keys. It is
+// intentionally small rather than pulling in a general-purpose TOML
+// dependency for a handful of scalar and array fields.
+func parseTOML(data []byte) (Config, error) {
+	cfg := Config{Forms: map[string]FormRule{}}
+
+	var (
+		rule    *Rule
+		inRules bool
+	)
+
+	for i, rawLine := range strings.Split(string(data), "\n") {
+		line := stripComment(rawLine)
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if line == "[[rules]]" {
+			if rule != nil {
+				cfg.Rules = append(cfg.Rules, *rule)
+			}
+
+			rule = &Rule{Config: Config{Forms: map[string]FormRule{}}}
+			inRules = true
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			return Config{}, fmt.Errorf("toml:%d: unsupported table %q", i+1, line)
+		}
+
+		key, value, err := splitKeyValue(line)
+		if err != nil {
+			return Config{}, fmt.Errorf("toml:%d: %w", i+1, err)
+		}
+
+		target := &cfg
+		if inRules {
+			target = &rule.Config
+		}
+
+		if err := setField(target, rule, inRules, key, value); err != nil {
+			return Config{}, fmt.Errorf("toml:%d: %w", i+1, err)
+		}
+	}
+
+	if rule != nil {
+		cfg.Rules = append(cfg.Rules, *rule)
+	}
+
+	return cfg, nil
+}
+
+func stripComment(line string) string {
+	inString := false
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+func splitKeyValue(line string) (key, value string, err error) {
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return "", "", fmt.Errorf("expected key = value, got %q", line)
+	}
+
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), nil
+}
+
+// setField assigns a parsed value to the right place in cfg: either a
+// top-level Config field, a "forms.<name>.<field>" entry, or (inside a
+// "[[rules]]" block) the special "paths" key.
+func setField(cfg *Config, rule *Rule, inRules bool, key, value string) error {
+	if inRules && key == "paths" {
+		paths, err := parseStringArray(value)
+		if err != nil {
+			return err
+		}
+
+		rule.Paths = paths
+
+		return nil
+	}
+
+	if strings.HasPrefix(key, "forms.") {
+		return setFormField(cfg, key, value)
+	}
+
+	switch key {
+	case "indent":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("indent: %w", err)
+		}
+
+		cfg.Indent = n
+	case "max_width":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("max_width: %w", err)
+		}
+
+		cfg.MaxWidth = n
+	case "dialect":
+		s, err := parseString(value)
+		if err != nil {
+			return err
+		}
+
+		cfg.Dialect = Dialect(s)
+	case "import":
+		imp, err := parseString(value)
+		if err == nil {
+			cfg.Imports = append(cfg.Imports, imp)
+			return nil
+		}
+
+		imports, err := parseStringArray(value)
+		if err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+
+		cfg.Imports = append(cfg.Imports, imports...)
+	case "scripts":
+		scripts, err := parseStringArray(value)
+		if err != nil {
+			return fmt.Errorf("scripts: %w", err)
+		}
+
+		cfg.Scripts = append(cfg.Scripts, scripts...)
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	return nil
+}
+
+// setFormField handles "forms.<name>.indent = <n>" keys.
+func setFormField(cfg *Config, key, value string) error {
+	parts := strings.SplitN(strings.TrimPrefix(key, "forms."), ".", 2)
+	if len(parts) != 2 || parts[1] != "indent" {
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	if cfg.Forms == nil {
+		cfg.Forms = map[string]FormRule{}
+	}
+
+	cfg.Forms[parts[0]] = FormRule{Indent: n}
+
+	return nil
+}
+
+func parseString(value string) (string, error) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %q", value)
+	}
+
+	return value[1 : len(value)-1], nil
+}
+
+func parseStringArray(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '[' || value[len(value)-1] != ']' {
+		return nil, fmt.Errorf("expected an array, got %q", value)
+	}
+
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	if inner == "" {
+		return nil, nil
+	}
+
+	var result []string
+
+	for _, item := range strings.Split(inner, ",") {
+		s, err := parseString(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, s)
+	}
+
+	return result, nil
+}
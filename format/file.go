@@ -0,0 +1,37 @@
+package format
+
+import (
+	"fmt"
+	"os"
+)
+
+// File formats the file at path and either writes the result back to disk
+// (write) or prints it to stdout.
+func File(path string, write bool, opts Options) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+
+	out, changed, err := Diff(src, opts)
+	if err != nil {
+		return fmt.Errorf("format: %s: %w", path, err)
+	}
+
+	if !write {
+		_, err := os.Stdout.Write(out)
+
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+
+	return os.WriteFile(path, out, info.Mode().Perm())
+}
@@ -0,0 +1,70 @@
+// Package format implements the Scheme source formatter at the core of
+// schemat. It parses source into a minimal s-expression tree and prints it
+// back out with normalized whitespace and indentation.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/raviqqe/schemat/script"
+)
+
+// Options controls how source is formatted.
+type Options struct {
+	// IndentSize is the number of spaces per indentation level.
+	IndentSize int
+
+	// MaxWidth is the preferred maximum line length. A value of 0 disables
+	// the width-based heuristics and always breaks lists onto multiple
+	// lines.
+	MaxWidth int
+
+	// Rules, if non-nil, supplies per-form indentation overrides from
+	// user-defined scripts (see the script package). A list whose head
+	// symbol has no registered rule falls back to the default body
+	// indentation.
+	Rules *script.Engine
+}
+
+// DefaultOptions are the options used when none are given.
+var DefaultOptions = Options{IndentSize: 2, MaxWidth: 80}
+
+// Source formats a single buffer of Scheme source and returns the result.
+func Source(src []byte, opts Options) ([]byte, error) {
+	nodes, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("format: %w", err)
+	}
+
+	return print(nodes, opts), nil
+}
+
+// Stream reads Scheme source from r, formats it, and writes the result to w.
+func Stream(r io.Reader, w io.Writer, opts Options) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+
+	out, err := Source(src, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+
+	return err
+}
+
+// Diff reports whether formatting src with opts would change it, and
+// returns the formatted result.
+func Diff(src []byte, opts Options) (formatted []byte, changed bool, err error) {
+	out, err := Source(src, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return out, !bytes.Equal(src, out), nil
+}
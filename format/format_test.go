@@ -0,0 +1,71 @@
+package format
+
+import "testing"
+
+func TestSourceCollapsesShortLists(t *testing.T) {
+	out, err := Source([]byte("(define   x\n  1)"), DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "(define x 1)\n"
+	if string(out) != want {
+		t.Errorf("Source() = %q, want %q", out, want)
+	}
+}
+
+func TestSourceBreaksLongLists(t *testing.T) {
+	opts := Options{IndentSize: 2, MaxWidth: 10}
+
+	out, err := Source([]byte("(define x 1)"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "(define\n  x\n  1)\n"
+	if string(out) != want {
+		t.Errorf("Source() = %q, want %q", out, want)
+	}
+}
+
+func TestDiffReportsNoChangeForAlreadyFormattedSource(t *testing.T) {
+	src := []byte("(define x 1)\n")
+
+	_, changed, err := Diff(src, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed {
+		t.Errorf("Diff() reported a change for already-formatted source")
+	}
+}
+
+func TestSourceUnterminatedListIsAnError(t *testing.T) {
+	if _, err := Source([]byte("(define x 1"), DefaultOptions); err == nil {
+		t.Errorf("Source() = nil error, want error for unterminated list")
+	}
+}
+
+func TestSourceStrayTopLevelCloserIsAnError(t *testing.T) {
+	// A stray closer must never be treated as "end of input": that would
+	// silently drop every form that follows it instead of failing.
+	if _, err := Source([]byte("(foo))(bar)"), DefaultOptions); err == nil {
+		t.Errorf("Source() = nil error, want error for an unmatched top-level closer")
+	}
+}
+
+func TestSourceNeverCollapsesAListContainingAComment(t *testing.T) {
+	// A ";" comment runs to end-of-line, so collapsing a list around one
+	// onto a single line would swallow every sibling and closing paren
+	// that follows it into the comment.
+	out, err := Source([]byte("(foo ; comment\n bar)"), DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "(foo\n  ; comment\n  bar)\n"
+	if string(out) != want {
+		t.Errorf("Source() = %q, want %q", out, want)
+	}
+}
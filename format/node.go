@@ -0,0 +1,27 @@
+package format
+
+// kind identifies the shape of a parsed node.
+type kind int
+
+const (
+	kindAtom kind = iota
+	kindString
+	kindComment
+	kindList
+)
+
+// node is a minimal representation of Scheme source: enough to re-print it
+// with normalized whitespace without needing a full semantic parser.
+type node struct {
+	kind kind
+
+	// text holds the literal text for atom, string and comment nodes.
+	text string
+
+	// open and close hold the bracket characters for list nodes, since
+	// Scheme source mixes "(...)", "[...]" and "{...}".
+	open, close byte
+
+	// children holds the elements of a list node.
+	children []node
+}
@@ -0,0 +1,144 @@
+package format
+
+import "fmt"
+
+// parse turns raw source into a flat sequence of top-level nodes.
+func parse(src []byte) ([]node, error) {
+	p := &parser{src: src}
+
+	nodes, err := p.parseSequence(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+type parser struct {
+	src []byte
+	pos int
+}
+
+var closers = map[byte]byte{'(': ')', '[': ']', '{': '}'}
+
+// parseSequence parses nodes until EOF or any closing bracket is found.
+// depth is the current list nesting: at depth 0 a closer is an error, since
+// there is no list for it to close; deeper, it ends the sequence and
+// parseList checks that it matches the opener.
+func (p *parser) parseSequence(depth int) ([]node, error) {
+	var nodes []node
+
+	for {
+		p.skipSpace()
+
+		if p.pos >= len(p.src) {
+			return nodes, nil
+		}
+
+		c := p.src[p.pos]
+
+		switch {
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("format: unmatched %q at offset %d", c, p.pos)
+			}
+
+			return nodes, nil
+		case c == '(' || c == '[' || c == '{':
+			n, err := p.parseList(depth)
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, n)
+		case c == '"':
+			n, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, n)
+		case c == ';':
+			nodes = append(nodes, p.parseLineComment())
+		default:
+			nodes = append(nodes, p.parseAtom())
+		}
+	}
+}
+
+func (p *parser) parseList(depth int) (node, error) {
+	open := p.src[p.pos]
+	p.pos++
+
+	children, err := p.parseSequence(depth + 1)
+	if err != nil {
+		return node{}, err
+	}
+
+	if p.pos >= len(p.src) || p.src[p.pos] != closers[open] {
+		return node{}, fmt.Errorf("format: unterminated list starting with %q at offset %d", open, p.pos)
+	}
+	p.pos++
+
+	return node{kind: kindList, open: open, close: closers[open], children: children}, nil
+}
+
+func (p *parser) parseString() (node, error) {
+	start := p.pos
+	p.pos++ // opening quote
+
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos += 2
+			continue
+		case '"':
+			p.pos++
+
+			return node{kind: kindString, text: string(p.src[start:p.pos])}, nil
+		}
+
+		p.pos++
+	}
+
+	return node{}, fmt.Errorf("format: unterminated string starting at offset %d", start)
+}
+
+func (p *parser) parseLineComment() node {
+	start := p.pos
+
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+
+	return node{kind: kindComment, text: string(p.src[start:p.pos])}
+}
+
+func (p *parser) parseAtom() node {
+	start := p.pos
+
+	for p.pos < len(p.src) && !isDelimiter(p.src[p.pos]) {
+		p.pos++
+	}
+
+	return node{kind: kindAtom, text: string(p.src[start:p.pos])}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '[', ']', '{', '}', '"', ';':
+		return true
+	}
+
+	return isSpace(c)
+}
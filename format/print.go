@@ -0,0 +1,211 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/raviqqe/schemat/script"
+)
+
+// print renders nodes back to source, one top-level node per line (Scheme
+// source is a sequence of independent top-level forms).
+func print(nodes []node, opts Options) []byte {
+	var buf bytes.Buffer
+
+	for i, n := range nodes {
+		if i > 0 {
+			buf.WriteString("\n")
+			if n.kind != kindComment {
+				buf.WriteString("\n")
+			}
+		}
+
+		writeNode(&buf, n, 0, opts)
+	}
+
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
+
+func writeNode(buf *bytes.Buffer, n node, col int, opts Options) {
+	switch n.kind {
+	case kindAtom, kindString, kindComment:
+		buf.WriteString(n.text)
+	case kindList:
+		writeList(buf, n, col, opts)
+	}
+}
+
+func writeList(buf *bytes.Buffer, n node, col int, opts Options) {
+	oneLine := render(n)
+
+	if opts.MaxWidth == 0 || col+len(oneLine) > opts.MaxWidth || containsComment(n) {
+		writeListMultiLine(buf, n, col, opts)
+		return
+	}
+
+	buf.WriteString(oneLine)
+}
+
+// containsComment reports whether n is, or at any depth contains, a
+// line comment. A ";" comment runs to end-of-line, so a list collapsed
+// onto one line around one would swallow every sibling and closing
+// paren that follows it into the comment; such a list must always be
+// printed one child per line instead.
+func containsComment(n node) bool {
+	if n.kind == kindComment {
+		return true
+	}
+
+	for _, c := range n.children {
+		if containsComment(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeListMultiLine prints a list with one child per line, using whatever
+// indentation a user-registered rule specifies for the list's head symbol,
+// or falling back to the default "body" indentation used by most Scheme
+// special forms and function applications alike.
+func writeListMultiLine(buf *bytes.Buffer, n node, col int, opts Options) {
+	if opts.Rules != nil {
+		if head, ok := headSymbol(n); ok && opts.Rules.HasRule(head) {
+			if spec, err := opts.Rules.Indent(nodeInfo(n, col)); err == nil {
+				writeListWithSpec(buf, n, col, opts, spec)
+				return
+			}
+		}
+	}
+
+	writeListBody(buf, n, col, opts, col+opts.IndentSize)
+}
+
+func writeListWithSpec(buf *bytes.Buffer, n node, col int, opts Options, spec script.IndentSpec) {
+	if spec.Kind == "align" {
+		writeListAligned(buf, n, col, opts, spec.Pivot)
+		return
+	}
+
+	writeListBody(buf, n, col, opts, col+spec.BodyStart)
+}
+
+func writeListBody(buf *bytes.Buffer, n node, col int, opts Options, bodyCol int) {
+	buf.WriteByte(n.open)
+
+	for i, c := range n.children {
+		if i == 0 {
+			writeNode(buf, c, col+1, opts)
+			continue
+		}
+
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(" ", bodyCol))
+		writeNode(buf, c, bodyCol, opts)
+	}
+
+	buf.WriteByte(n.close)
+}
+
+// writeListAligned prints a list with its first pivot+1 children on the
+// opening line and every child after that aligned under the column the
+// pivot-th child started at. An out-of-range pivot (nothing to align
+// under) falls back to the default body indentation.
+func writeListAligned(buf *bytes.Buffer, n node, col int, opts Options, pivot int) {
+	if pivot < 0 || pivot >= len(n.children)-1 {
+		writeListBody(buf, n, col, opts, col+opts.IndentSize)
+		return
+	}
+
+	buf.WriteByte(n.open)
+
+	lineCol := col + 1
+	pivotCol := lineCol
+
+	for i := 0; i <= pivot; i++ {
+		if i > 0 {
+			buf.WriteByte(' ')
+			lineCol++
+		}
+
+		if i == pivot {
+			pivotCol = lineCol
+		}
+
+		writeNode(buf, n.children[i], lineCol, opts)
+		lineCol += len(render(n.children[i]))
+	}
+
+	for i := pivot + 1; i < len(n.children); i++ {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(" ", pivotCol))
+		writeNode(buf, n.children[i], pivotCol, opts)
+	}
+
+	buf.WriteByte(n.close)
+}
+
+func headSymbol(n node) (string, bool) {
+	if len(n.children) == 0 || n.children[0].kind != kindAtom {
+		return "", false
+	}
+
+	return n.children[0].text, true
+}
+
+func nodeInfo(n node, col int) script.NodeInfo {
+	kinds := make([]string, len(n.children))
+	for i, c := range n.children {
+		kinds[i] = kindName(c.kind)
+	}
+
+	head, _ := headSymbol(n)
+
+	return script.NodeInfo{HeadSymbol: head, ChildCount: len(n.children), ChildKinds: kinds, Column: col}
+}
+
+func kindName(k kind) string {
+	switch k {
+	case kindAtom:
+		return "atom"
+	case kindString:
+		return "string"
+	case kindComment:
+		return "comment"
+	case kindList:
+		return "list"
+	default:
+		return "unknown"
+	}
+}
+
+// render prints a node on a single line, used to measure whether a list
+// fits within the configured width.
+func render(n node) string {
+	var buf bytes.Buffer
+	renderTo(&buf, n)
+
+	return buf.String()
+}
+
+func renderTo(buf *bytes.Buffer, n node) {
+	switch n.kind {
+	case kindAtom, kindString, kindComment:
+		buf.WriteString(n.text)
+	case kindList:
+		buf.WriteByte(n.open)
+
+		for i, c := range n.children {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+
+			renderTo(buf, c)
+		}
+
+		buf.WriteByte(n.close)
+	}
+}
@@ -0,0 +1,65 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raviqqe/schemat/script"
+)
+
+func TestSourceUsesRegisteredBodyRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.js")
+	err := os.WriteFile(path, []byte(`
+schemat.registerRule("for/fold", function(node) {
+	return {kind: "body", bodyStart: node.column + 4};
+});
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := script.NewEngine()
+	if err := engine.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Source([]byte("(for/fold a b)"), Options{IndentSize: 2, MaxWidth: 1, Rules: engine})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "(for/fold\n    a\n    b)\n"
+	if string(out) != want {
+		t.Errorf("Source() = %q, want %q", out, want)
+	}
+}
+
+func TestSourceUsesRegisteredAlignRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.js")
+	err := os.WriteFile(path, []byte(`
+schemat.registerRule("match-lambda*", function(node) {
+	return {kind: "align", pivot: 1};
+});
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := script.NewEngine()
+	if err := engine.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Source([]byte(`(match-lambda* a b)`), Options{IndentSize: 2, MaxWidth: 1, Rules: engine})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	align := strings.Repeat(" ", len("(match-lambda* "))
+	want := "(match-lambda* a\n" + align + "b)\n"
+	if string(out) != want {
+		t.Errorf("Source() = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,90 @@
+package lsp
+
+import (
+	"fmt"
+
+	memdb "github.com/hashicorp/go-memdb"
+)
+
+// document is an open buffer, keyed by URI, with the version the client
+// last reported so handlers can reason about which edit a document's
+// content corresponds to.
+type document struct {
+	URI     string
+	Version int
+	Text    string
+}
+
+var documentSchema = &memdb.DBSchema{
+	Tables: map[string]*memdb.TableSchema{
+		"document": {
+			Name: "document",
+			Indexes: map[string]*memdb.IndexSchema{
+				"id": {
+					Name:    "id",
+					Unique:  true,
+					Indexer: &memdb.StringFieldIndex{Field: "URI"},
+				},
+			},
+		},
+	},
+}
+
+// documentStore holds every open document in memory, indexed by URI.
+type documentStore struct {
+	db *memdb.MemDB
+}
+
+func newDocumentStore() (*documentStore, error) {
+	db, err := memdb.NewMemDB(documentSchema)
+	if err != nil {
+		return nil, fmt.Errorf("lsp: %w", err)
+	}
+
+	return &documentStore{db: db}, nil
+}
+
+// put records a document's content, replacing any previous content stored
+// under the same URI. It backs both didOpen and didChange, since schemat
+// only supports full-document sync.
+func (s *documentStore) put(uri string, version int, text string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	if err := txn.Insert("document", &document{URI: uri, Version: version, Text: text}); err != nil {
+		return fmt.Errorf("lsp: %w", err)
+	}
+
+	txn.Commit()
+
+	return nil
+}
+
+func (s *documentStore) get(uri string) (*document, error) {
+	txn := s.db.Txn(false)
+	defer txn.Abort()
+
+	raw, err := txn.First("document", "id", uri)
+	if err != nil {
+		return nil, fmt.Errorf("lsp: %w", err)
+	}
+
+	if raw == nil {
+		return nil, fmt.Errorf("lsp: %s is not open", uri)
+	}
+
+	return raw.(*document), nil
+}
+
+func (s *documentStore) delete(uri string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	if _, err := txn.DeleteAll("document", "id", uri); err != nil {
+		return fmt.Errorf("lsp: %w", err)
+	}
+
+	txn.Commit()
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package lsp
+
+import "strings"
+
+// diffEdits returns the TextEdits needed to turn oldText into newText. It
+// trims the lines common to both texts' start and end, so the returned edit
+// (if any) spans only the minimal differing block of lines rather than the
+// whole buffer -- this is what lets editors preserve cursor position and
+// undo history outside the changed region. base is added to every line
+// number, so callers formatting a sub-range of a document can report
+// positions in terms of the full document.
+func diffEdits(oldText, newText string, base Position) []TextEdit {
+	if oldText == newText {
+		return nil
+	}
+
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	prefix := commonLen(oldLines, newLines)
+	oldRest := oldLines[prefix:]
+	newRest := newLines[prefix:]
+
+	suffix := commonLen(reversed(oldRest), reversed(newRest))
+	oldMiddle := oldRest[:len(oldRest)-suffix]
+	newMiddle := newRest[:len(newRest)-suffix]
+
+	return []TextEdit{{
+		Range: Range{
+			Start: Position{Line: base.Line + prefix, Character: 0},
+			End:   Position{Line: base.Line + prefix + len(oldMiddle), Character: 0},
+		},
+		NewText: strings.Join(newMiddle, ""),
+	}}
+}
+
+// splitLines splits s into lines, each still ending with its "\n" (except
+// possibly the last), so joining the result reproduces s exactly.
+func splitLines(s string) []string {
+	var lines []string
+
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			lines = append(lines, s[start:i+1])
+			start = i + 1
+		}
+	}
+
+	if start < len(s) {
+		lines = append(lines, s[start:])
+	}
+
+	return lines
+}
+
+func commonLen(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
+
+func reversed(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[len(lines)-1-i] = line
+	}
+
+	return out
+}
+
+// extractRange returns the substring of text delimited by rng, a
+// line/UTF-16-character span as used by the LSP.
+func extractRange(text string, rng Range) string {
+	lines := splitLines(text)
+	if rng.Start.Line >= len(lines) {
+		return ""
+	}
+
+	if rng.End.Line == rng.Start.Line {
+		line := lines[rng.Start.Line]
+		start := utf16OffsetToByte(line, rng.Start.Character)
+		end := utf16OffsetToByte(line, rng.End.Character)
+
+		if end < start {
+			end = start
+		}
+
+		return line[start:end]
+	}
+
+	var buf strings.Builder
+
+	first := lines[rng.Start.Line]
+	buf.WriteString(first[utf16OffsetToByte(first, rng.Start.Character):])
+
+	for i := rng.Start.Line + 1; i < rng.End.Line && i < len(lines); i++ {
+		buf.WriteString(lines[i])
+	}
+
+	if rng.End.Line < len(lines) {
+		last := lines[rng.End.Line]
+		buf.WriteString(last[:utf16OffsetToByte(last, rng.End.Character)])
+	}
+
+	return buf.String()
+}
+
+// utf16OffsetToByte converts n, a count of UTF-16 code units (the LSP's
+// Position.character) into a byte offset into line, clamping to line's
+// bounds and never splitting a multi-byte rune. Runes outside the basic
+// multilingual plane count as 2 UTF-16 code units (a surrogate pair), same
+// as every LSP client encodes positions.
+func utf16OffsetToByte(line string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	units := 0
+
+	for i, r := range line {
+		if units >= n {
+			return i
+		}
+
+		if r > 0xFFFF {
+			units += 2
+		} else {
+			units++
+		}
+	}
+
+	return len(line)
+}
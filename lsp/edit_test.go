@@ -0,0 +1,77 @@
+package lsp
+
+import "testing"
+
+func TestDiffEditsNoChange(t *testing.T) {
+	if edits := diffEdits("(a b)\n", "(a b)\n", Position{}); edits != nil {
+		t.Errorf("diffEdits() = %v, want nil", edits)
+	}
+}
+
+func TestDiffEditsTrimsCommonLines(t *testing.T) {
+	before := "(a)\n(b   c)\n(d)\n"
+	after := "(a)\n(b c)\n(d)\n"
+
+	edits := diffEdits(before, after, Position{})
+	if len(edits) != 1 {
+		t.Fatalf("diffEdits() = %v, want exactly one edit", edits)
+	}
+
+	e := edits[0]
+	if e.Range.Start != (Position{Line: 1, Character: 0}) || e.Range.End != (Position{Line: 2, Character: 0}) {
+		t.Errorf("Range = %+v, want only line 1 replaced", e.Range)
+	}
+
+	if e.NewText != "(b c)\n" {
+		t.Errorf("NewText = %q, want %q", e.NewText, "(b c)\n")
+	}
+}
+
+func TestDiffEditsAppliesAtBaseOffset(t *testing.T) {
+	edits := diffEdits("(a  b)", "(a b)", Position{Line: 5})
+	if len(edits) != 1 {
+		t.Fatalf("diffEdits() = %v, want exactly one edit", edits)
+	}
+
+	if edits[0].Range.Start.Line != 5 {
+		t.Errorf("Range.Start.Line = %d, want 5", edits[0].Range.Start.Line)
+	}
+}
+
+func TestExtractRangeSingleLine(t *testing.T) {
+	got := extractRange("(a b c)\n", Range{
+		Start: Position{Line: 0, Character: 1},
+		End:   Position{Line: 0, Character: 4},
+	})
+
+	if got != "a b" {
+		t.Errorf("extractRange() = %q, want %q", got, "a b")
+	}
+}
+
+func TestExtractRangeHandlesMultiByteRunes(t *testing.T) {
+	// Character is a UTF-16 code unit offset, not a byte offset: "héllo" is
+	// 5 UTF-16 units but 6 bytes in UTF-8 ("é" is 2 bytes), so indexing by
+	// byte would clip the last character and return "héll".
+	got := extractRange("(héllo world)\n", Range{
+		Start: Position{Line: 0, Character: 1},
+		End:   Position{Line: 0, Character: 6},
+	})
+
+	if got != "héllo" {
+		t.Errorf("extractRange() = %q, want %q", got, "héllo")
+	}
+}
+
+func TestExtractRangeMultiLine(t *testing.T) {
+	text := "(a\nb\nc)\n"
+
+	got := extractRange(text, Range{
+		Start: Position{Line: 0, Character: 1},
+		End:   Position{Line: 2, Character: 1},
+	})
+
+	if got != "a\nb\nc" {
+		t.Errorf("extractRange() = %q, want %q", got, "a\nb\nc")
+	}
+}
@@ -0,0 +1,90 @@
+package lsp
+
+// This file defines the small slice of the Language Server Protocol that
+// schemat implements. Field names and shapes follow the spec so the JSON
+// encodes exactly as editors expect it.
+
+// Position is a zero-based line and UTF-16 character offset within a text
+// document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a half-open span between two positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit replaces the text within Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// TextDocumentIdentifier refers to an open document by URI.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier additionally carries the document's
+// version, as sent with didChange notifications.
+type VersionedTextDocumentIdentifier struct {
+	TextDocumentIdentifier
+
+	Version int `json:"version"`
+}
+
+// TextDocumentItem is the full content of a document as sent with didOpen.
+type TextDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// DidOpenTextDocumentParams are the params of textDocument/didOpen.
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// TextDocumentContentChangeEvent describes one change to a document. Range
+// nil means the document's full content was replaced with Text, which is
+// the only form of sync schemat requests (full sync, not incremental).
+type TextDocumentContentChangeEvent struct {
+	Range *Range `json:"range,omitempty"`
+	Text  string `json:"text"`
+}
+
+// DidChangeTextDocumentParams are the params of textDocument/didChange.
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// DidCloseTextDocumentParams are the params of textDocument/didClose.
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// DocumentFormattingParams are the params of textDocument/formatting. The
+// request also carries a client "options" field (tab size, spaces-vs-tabs)
+// per the spec, but schemat's formatting is driven entirely by project
+// config, so that field is intentionally not modeled here.
+type DocumentFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// DocumentRangeFormattingParams are the params of
+// textDocument/rangeFormatting. See DocumentFormattingParams on why the
+// client "options" field isn't modeled.
+type DocumentRangeFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// textDocumentSyncKindFull requests that clients send the document's full
+// text on every change, which keeps didChange handling simple; precision
+// for the editor comes from the TextEdits schemat returns, not from
+// incremental sync.
+const textDocumentSyncKindFull = 1
@@ -0,0 +1,197 @@
+// Package lsp implements a Language Server Protocol server, over stdio, for
+// schemat: textDocument/formatting, textDocument/rangeFormatting and
+// textDocument/didChange, so editors can format Scheme buffers
+// incrementally instead of shelling out to the CLI per keystroke. It
+// reuses the format package's pipeline and returns precise TextEdits
+// (diffed against the buffer) rather than full-document replacements.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raviqqe/schemat/config"
+	"github.com/raviqqe/schemat/format"
+)
+
+// Server is a running LSP session. Create one with NewServer per
+// connection; it is not safe for concurrent use.
+type Server struct {
+	loader *config.Loader
+	docs   *documentStore
+}
+
+// NewServer creates a Server that resolves formatting options through
+// loader.
+func NewServer(loader *config.Loader) (*Server, error) {
+	docs, err := newDocumentStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{loader: loader, docs: docs}, nil
+}
+
+// Serve reads JSON-RPC requests from r and writes responses to w until r
+// reaches EOF or an "exit" notification is received.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	for {
+		body, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("lsp: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return fmt.Errorf("lsp: %w", err)
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, handleErr := s.handle(req.Method, req.Params)
+
+		if len(req.ID) == 0 {
+			continue // notification: no response expected
+		}
+
+		resp := response{JSONRPC: "2.0", ID: req.ID}
+		if handleErr != nil {
+			resp.Error = &responseError{Code: internalError, Message: handleErr.Error()}
+		} else {
+			resp.Result = result
+		}
+
+		if err := writeMessage(w, resp); err != nil {
+			return fmt.Errorf("lsp: %w", err)
+		}
+	}
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return initializeResult(), nil
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		return nil, s.docs.put(p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text)
+	case "textDocument/didChange":
+		return nil, s.didChange(params)
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		return nil, s.docs.delete(p.TextDocument.URI)
+	case "textDocument/formatting":
+		var p DocumentFormattingParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.format(p.TextDocument.URI, nil)
+	case "textDocument/rangeFormatting":
+		var p DocumentRangeFormattingParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.format(p.TextDocument.URI, &p.Range)
+	default:
+		// Unknown requests and notifications are silently ignored, per the
+		// LSP spec's recommendation for forward compatibility.
+		return nil, nil
+	}
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+
+	if len(p.ContentChanges) == 0 {
+		return nil
+	}
+
+	// Full sync only: the latest change carries the document's entire text.
+	text := p.ContentChanges[len(p.ContentChanges)-1].Text
+
+	return s.docs.put(p.TextDocument.URI, p.TextDocument.Version, text)
+}
+
+// format resolves the formatting edits for a document, optionally scoped to
+// rng (nil means the whole document).
+func (s *Server) format(uri string, rng *Range) ([]TextEdit, error) {
+	doc, err := s.docs.get(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.loader.Load(uriToPath(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.loader.FormatOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	region := doc.Text
+	base := Position{}
+
+	if rng != nil {
+		region = extractRange(doc.Text, *rng)
+		base = rng.Start
+	}
+
+	out, err := format.Source([]byte(region), opts)
+	if err != nil {
+		return nil, nil // a buffer mid-edit may be momentarily unparseable
+	}
+
+	if rng != nil && strings.TrimSpace(region) != "" && len(strings.TrimSpace(string(out))) == 0 {
+		// An arbitrary selection need not align to complete top-level forms
+		// (e.g. it can start or end mid-form). A stray closer or an unclosed
+		// list already makes format.Source fail above; this guard also treats
+		// blank output for a non-blank selection as unparseable, rather than
+		// returning a TextEdit that deletes the user's selection.
+		return nil, nil
+	}
+
+	return diffEdits(region, string(out), base), nil
+}
+
+func initializeResult() map[string]any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":                textDocumentSyncKindFull,
+			"documentFormattingProvider":      true,
+			"documentRangeFormattingProvider": true,
+		},
+	}
+}
+
+func uriToPath(uri string) string {
+	const prefix = "file://"
+	if strings.HasPrefix(uri, prefix) {
+		return uri[len(prefix):]
+	}
+
+	return uri
+}
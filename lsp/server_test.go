@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/raviqqe/schemat/config"
+)
+
+func frame(t *testing.T, method string, id int, params any) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": json.RawMessage(body)}
+	if id != 0 {
+		msg["id"] = id
+	}
+
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []byte(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(encoded), encoded))
+}
+
+func TestServeFormatsDocument(t *testing.T) {
+	server, err := NewServer(config.NewLoader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var in bytes.Buffer
+	in.Write(frame(t, "textDocument/didOpen", 0, DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: "file:///a.scm", Version: 1, Text: "(a  b)"},
+	}))
+	in.Write(frame(t, "textDocument/formatting", 1, DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///a.scm"},
+	}))
+
+	var out bytes.Buffer
+	if err := server.Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := readMessageFromString(out.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp struct {
+		Result []TextEdit `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Result) != 1 || resp.Result[0].NewText != "(a b)\n" {
+		t.Errorf("formatting result = %+v, want a single edit inserting %q", resp.Result, "(a b)\n")
+	}
+}
+
+func TestServeRangeFormattingIgnoresNonFormAlignedSelection(t *testing.T) {
+	server, err := NewServer(config.NewLoader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "(foo bar) (baz qux)\n"
+
+	var in bytes.Buffer
+	in.Write(frame(t, "textDocument/didOpen", 0, DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: "file:///a.scm", Version: 1, Text: text},
+	}))
+	// Selects ") (baz", which straddles the boundary between the two forms
+	// rather than spanning a complete one.
+	in.Write(frame(t, "textDocument/rangeFormatting", 1, DocumentRangeFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///a.scm"},
+		Range: Range{
+			Start: Position{Line: 0, Character: 8},
+			End:   Position{Line: 0, Character: 14},
+		},
+	}))
+
+	var out bytes.Buffer
+	if err := server.Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := readMessageFromString(out.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp struct {
+		Result []TextEdit `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Result) != 0 {
+		t.Errorf("rangeFormatting result = %+v, want no edits for a non-form-aligned selection", resp.Result)
+	}
+}
+
+// readMessageFromString extracts the body of the first framed message in s,
+// mirroring readMessage without requiring a *bufio.Reader in the test.
+func readMessageFromString(s string) ([]byte, error) {
+	sep := "\r\n\r\n"
+
+	i := bytes.Index([]byte(s), []byte(sep))
+	if i < 0 {
+		return nil, fmt.Errorf("no framed message found in %q", s)
+	}
+
+	return []byte(s[i+len(sep):]), nil
+}
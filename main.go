@@ -0,0 +1,152 @@
+// Command schemat formats Scheme source files.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/raviqqe/schemat/batch"
+	"github.com/raviqqe/schemat/config"
+	"github.com/raviqqe/schemat/format"
+	"github.com/raviqqe/schemat/lsp"
+
+	flag "github.com/spf13/pflag"
+)
+
+var (
+	write       = flag.BoolP("write", "w", false, "write result to (source) file instead of stdout")
+	indent      = flag.IntP("indent", "i", 0, "number of spaces per indentation level (overrides config)")
+	maxWidth    = flag.IntP("max-width", "", 0, "preferred maximum line length (overrides config)")
+	allowRemote = flag.Bool("allow-remote-config", false, `allow schemat.toml "import" entries to fetch http(s) URLs`)
+)
+
+func main() {
+	// Subcommands each have their own flag set, so they must be dispatched
+	// before the one-shot mode's flags are parsed.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lsp":
+			if err := runLSP(os.Args[2:]); err != nil {
+				fail(err)
+			}
+
+			return
+		case "check":
+			runBatch("check", os.Args[2:], batch.ModeCheck)
+			return
+		case "diff":
+			runBatch("diff", os.Args[2:], batch.ModeDiff)
+			return
+		}
+	}
+
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func runLSP(args []string) error {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	allowRemote := fs.Bool("allow-remote-config", false, `allow schemat.toml "import" entries to fetch http(s) URLs`)
+	fs.Parse(args)
+
+	loader := config.NewLoader()
+	loader.AllowRemoteImports = *allowRemote
+
+	server, err := lsp.NewServer(loader)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+func run(paths []string) error {
+	loader := config.NewLoader()
+	loader.AllowRemoteImports = *allowRemote
+
+	if len(paths) == 0 {
+		cfg, err := loader.Load(".")
+		if err != nil {
+			return err
+		}
+
+		opts, err := resolveOptions(loader, cfg)
+		if err != nil {
+			return err
+		}
+
+		return format.Stream(os.Stdin, os.Stdout, opts)
+	}
+
+	if !*write {
+		// Without -w, each file's formatted content goes to stdout in
+		// order; that's a poor fit for a worker pool, so this path stays
+		// sequential.
+		for _, p := range paths {
+			cfg, err := loader.Load(p)
+			if err != nil {
+				return err
+			}
+
+			opts, err := resolveOptions(loader, cfg)
+			if err != nil {
+				return err
+			}
+
+			if err := format.File(p, false, opts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	runner := &batch.Runner{
+		Loader:           loader,
+		Mode:             batch.ModeWrite,
+		IndentOverride:   *indent,
+		MaxWidthOverride: *maxWidth,
+	}
+
+	summary := runner.Run(paths)
+
+	for _, f := range summary.Files {
+		if f.Status == "error" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", f.Path, f.Error)
+		}
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("schemat: failed to format %d of %d file(s)", summary.Failed, len(paths))
+	}
+
+	return nil
+}
+
+// resolveOptions merges config file settings with any non-zero CLI flags
+// (flags take precedence; a flag left at, or set to, 0 keeps the config's
+// value).
+func resolveOptions(loader *config.Loader, cfg *config.Config) (format.Options, error) {
+	opts, err := loader.FormatOptions(cfg)
+	if err != nil {
+		return format.Options{}, err
+	}
+
+	if *indent != 0 {
+		opts.IndentSize = *indent
+	}
+
+	if *maxWidth != 0 {
+		opts.MaxWidth = *maxWidth
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,175 @@
+// Package script lets users extend schemat's indentation rules with
+// embedded ECMAScript, for the many dialect-specific special forms (e.g.
+// Racket's "for/fold", Guile's "match-lambda*", or a project's own
+// "with-*" macros) whose ideal indentation can't be inferred from syntax
+// alone. A script registers a handler per form with
+// schemat.registerRule(name, fn); each handler receives a description of
+// the list being printed and returns how to indent it.
+package script
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dop251/goja"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// programCacheSize bounds how many distinct scripts (by content) are kept
+// compiled at once. Plugin sets are small in practice; this is generous
+// headroom rather than a tuned limit.
+const programCacheSize = 64
+
+// programCache holds compiled scripts across Engines, keyed by a hash of
+// their source, so loading the same plugin from several project
+// directories in one batch run only compiles it once.
+var (
+	programCacheOnce sync.Once
+	programCache     *lru.Cache
+)
+
+func cache() *lru.Cache {
+	programCacheOnce.Do(func() {
+		c, err := lru.New(programCacheSize)
+		if err != nil {
+			// Only returns an error for a non-positive size, which
+			// programCacheSize never is.
+			panic(err)
+		}
+
+		programCache = c
+	})
+
+	return programCache
+}
+
+// Engine is an embedded ECMAScript runtime holding whatever indentation
+// rules its loaded scripts registered. Load is not safe for concurrent use;
+// once loading is done, HasRule and Indent may be called from several
+// goroutines, as Indent runs one rule at a time.
+type Engine struct {
+	mu      sync.Mutex
+	runtime *goja.Runtime
+	rules   map[string]goja.Callable
+}
+
+// NewEngine returns an Engine with no rules registered yet; call Load to
+// run plugin scripts against it.
+func NewEngine() *Engine {
+	e := &Engine{rules: map[string]goja.Callable{}}
+
+	e.runtime = goja.New()
+	// Host <-> script values use the struct's "json" tags (e.g. "bodyStart"
+	// rather than "BodyStart"), matching the plain-object shape the request
+	// describes rules receiving and returning.
+	e.runtime.SetFieldNameMapper(goja.TagFieldNameMapper("json", false))
+	e.runtime.Set("schemat", map[string]any{"registerRule": e.registerRule})
+
+	return e
+}
+
+func (e *Engine) registerRule(name string, fn goja.Value) error {
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		return fmt.Errorf("script: registerRule(%q, ...): second argument is not a function", name)
+	}
+
+	e.rules[name] = callable
+
+	return nil
+}
+
+// Load compiles and runs the ECMAScript file at path, registering whatever
+// rules it calls schemat.registerRule with onto e.
+func (e *Engine) Load(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("script: %w", err)
+	}
+
+	program, err := compile(path, src)
+	if err != nil {
+		return fmt.Errorf("script: %s: %w", path, err)
+	}
+
+	if _, err := e.runtime.RunProgram(program); err != nil {
+		return fmt.Errorf("script: %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func compile(path string, src []byte) (*goja.Program, error) {
+	sum := sha256.Sum256(src)
+	key := hex.EncodeToString(sum[:])
+
+	if v, ok := cache().Get(key); ok {
+		return v.(*goja.Program), nil
+	}
+
+	program, err := goja.Compile(path, string(src), false)
+	if err != nil {
+		return nil, err
+	}
+
+	cache().Add(key, program)
+
+	return program, nil
+}
+
+// HasRule reports whether a rule was registered for the given head symbol.
+func (e *Engine) HasRule(headSymbol string) bool {
+	_, ok := e.rules[headSymbol]
+
+	return ok
+}
+
+// NodeInfo is the minimal AST description passed to a registered rule: a
+// list's head symbol, its child count and kinds, and the column it starts
+// at. Rules never see the formatter's actual node type, so the plugin
+// interface stays stable across changes to the formatter's internals.
+type NodeInfo struct {
+	HeadSymbol string   `json:"headSymbol"`
+	ChildCount int      `json:"childCount"`
+	ChildKinds []string `json:"childKinds"`
+	Column     int      `json:"column"`
+}
+
+// IndentSpec is what a rule returns: either {kind: "body", bodyStart: N}
+// (the head stays on the opening line and every child after it is indented
+// N columns past the form's own column) or {kind: "align", pivot: N}
+// (children after the Nth, counted from zero, line up under the column of
+// the Nth child).
+type IndentSpec struct {
+	Kind      string `json:"kind"`
+	BodyStart int    `json:"bodyStart"`
+	Pivot     int    `json:"pivot"`
+}
+
+// Indent runs the rule registered for node.HeadSymbol and returns the
+// IndentSpec it produced. It is an error to call Indent for a symbol with
+// no registered rule; callers should check HasRule first.
+func (e *Engine) Indent(node NodeInfo) (IndentSpec, error) {
+	rule, ok := e.rules[node.HeadSymbol]
+	if !ok {
+		return IndentSpec{}, fmt.Errorf("script: no rule registered for %q", node.HeadSymbol)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result, err := rule(goja.Undefined(), e.runtime.ToValue(node))
+	if err != nil {
+		return IndentSpec{}, fmt.Errorf("script: %s: %w", node.HeadSymbol, err)
+	}
+
+	var spec IndentSpec
+	if err := e.runtime.ExportTo(result, &spec); err != nil {
+		return IndentSpec{}, fmt.Errorf("script: %s: invalid return value: %w", node.HeadSymbol, err)
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,61 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rules.js")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestEngineRegistersAndRunsRule(t *testing.T) {
+	path := writeScript(t, `
+schemat.registerRule("for/fold", function(node) {
+	return {kind: "body", bodyStart: node.column + 2};
+});
+`)
+
+	e := NewEngine()
+	if err := e.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if !e.HasRule("for/fold") {
+		t.Fatal("HasRule(\"for/fold\") = false, want true")
+	}
+
+	spec, err := e.Indent(NodeInfo{HeadSymbol: "for/fold", ChildCount: 2, Column: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Kind != "body" || spec.BodyStart != 6 {
+		t.Errorf("Indent() = %+v, want {Kind: body, BodyStart: 6}", spec)
+	}
+}
+
+func TestEngineIndentUnregisteredRuleIsAnError(t *testing.T) {
+	e := NewEngine()
+
+	if _, err := e.Indent(NodeInfo{HeadSymbol: "unknown"}); err == nil {
+		t.Error("Indent() = nil error, want error for unregistered rule")
+	}
+}
+
+func TestEngineRegisterRuleRejectsNonFunction(t *testing.T) {
+	path := writeScript(t, `schemat.registerRule("x", 42);`)
+
+	e := NewEngine()
+	if err := e.Load(path); err == nil {
+		t.Error("Load() = nil error, want error for non-function rule")
+	}
+}